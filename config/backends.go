@@ -0,0 +1,18 @@
+package config
+
+// Backends configures which tagreader backends are active and in what
+// order they are registered. The backend names are the ones the
+// backends are registered under in transform, currently "id3v2", "taglib",
+// "tag" and "filename".
+type Backends struct {
+	// Disabled lists backend names that must never be used, even though
+	// they are compiled in.
+	Disabled []string `json:"disabled"`
+
+	// Order overrides the default backend registration order. Backends not
+	// listed here are registered after the listed ones, in built-in order.
+	// An empty Order uses the built-in order. Registration order only
+	// breaks ties between backends of equal Priority claiming the same
+	// extension; it does not override a backend's Priority.
+	Order []string `json:"order"`
+}
@@ -0,0 +1,71 @@
+// Package config loads mp3sync's JSON configuration file.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Config holds the user-configurable settings for a sync run.
+type Config struct {
+	// Source is the root directory to sync files from.
+	Source string `json:"source"`
+
+	// Destination is the root directory to sync files to.
+	Destination string `json:"destination"`
+
+	// Backends configures the tagreader backends used to read metadata
+	// from source files.
+	Backends Backends `json:"backends"`
+
+	// Whitelist, if non-empty, restricts a sync to files whose tag info
+	// matches at least one of its values; a file matching none of them is
+	// skipped.
+	Whitelist Tag `json:"whitelist"`
+
+	// Blacklist skips any file whose tag info matches one of its values,
+	// checked after Whitelist.
+	Blacklist Tag `json:"blacklist"`
+
+	// Naming configures how destination file names and subfolders are
+	// built from a file's tag info.
+	Naming Naming `json:"naming"`
+
+	// ManifestPath is the path to the SQLite manifest database used to
+	// track previously synced files. Empty disables the manifest: tags of
+	// every file are read again on each run, and a file is synced whenever
+	// its destination is missing or differs in size from the source.
+	ManifestPath string `json:"manifestPath"`
+
+	// PathFilter configures include/exclude patterns applied while
+	// walking Source, before any file is opened.
+	PathFilter PathFilter `json:"pathFilter"`
+
+	// Transcode configures on-the-fly re-encoding of files that exceed a
+	// portable device's constraints. The zero value disables transcoding.
+	Transcode TranscodeProfile `json:"transcode"`
+
+	// Workers is how many files transform and filesync process
+	// concurrently. 0 or less defaults to runtime.NumCPU().
+	Workers int `json:"workers"`
+}
+
+var errLoadConfig = errors.New("failed to load config")
+
+// Load reads and parses the Config at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errLoadConfig, err)
+	}
+
+	var conf Config
+
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return nil, fmt.Errorf("%w: %v", errLoadConfig, err)
+	}
+
+	return &conf, nil
+}
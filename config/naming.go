@@ -0,0 +1,22 @@
+package config
+
+// Naming configures how destination file names and subfolders are derived
+// from a file's tag info.
+type Naming struct {
+	// NamingTemplate is a text/template producing the destination file
+	// name (without directory), e.g.
+	// `{{.Artist}} - {{.Album}} ({{.Year}}) - {{printf "%02d" .Track}} - {{.Title}}{{.Ext}}`.
+	// Empty uses the built-in default format.
+	NamingTemplate string `json:"namingTemplate"`
+
+	// FolderTemplate is a text/template producing the destination
+	// subfolder, relative to Config.Destination, e.g.
+	// `{{firstLetter .AlbumArtist}}`. Empty uses the built-in single-letter
+	// A-Z/# scheme based on AlbumArtist.
+	FolderTemplate string `json:"folderTemplate"`
+
+	// SanitizeMap maps characters that are unsafe on the destination
+	// filesystem to their replacement, e.g. FAT32/exFAT/NTFS reserved
+	// characters. Empty uses the built-in defaults.
+	SanitizeMap map[string]string `json:"sanitizeMap"`
+}
@@ -0,0 +1,10 @@
+package config
+
+// PathFilter configures gitignore-style include/exclude patterns applied
+// to source paths before they are ever opened, on top of any
+// .mp3syncignore files found while walking. Patterns support "**",
+// negation with a leading "!", and directory-only matches ("foo/").
+type PathFilter struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
@@ -0,0 +1,34 @@
+package config
+
+import "strings"
+
+// Tag is a whitelist or blacklist of tag field values, keyed by tag field
+// name (e.g. "genre", "artist"), used to decide whether a file should be
+// included in a sync.
+type Tag map[string][]string
+
+// tagFields is implemented by anything Tag can match against. It mirrors
+// tagreader.Info without importing that package, to keep config free of a
+// dependency on the tag-reading backends.
+type tagFields interface {
+	Field(name string) string
+}
+
+// Contains reports whether any field of t is present in info's
+// corresponding field, case-insensitively.
+func (t Tag) Contains(info tagFields) bool {
+	for field, values := range t {
+		actual := info.Field(strings.ToLower(field))
+		if actual == "" {
+			continue
+		}
+
+		for _, v := range values {
+			if strings.EqualFold(actual, v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
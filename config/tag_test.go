@@ -0,0 +1,55 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/rebel-l/mp3sync/config"
+)
+
+type stubFields map[string]string
+
+func (s stubFields) Field(name string) string {
+	return s[name]
+}
+
+func TestTag_Contains(t *testing.T) {
+	tests := map[string]struct {
+		tag  config.Tag
+		info stubFields
+		want bool
+	}{
+		"matches case-insensitively": {
+			tag:  config.Tag{"genre": {"Podcast"}},
+			info: stubFields{"genre": "PODCAST"},
+			want: true,
+		},
+		"matches on any listed value": {
+			tag:  config.Tag{"artist": {"Foo", "Bar"}},
+			info: stubFields{"artist": "bar"},
+			want: true,
+		},
+		"no match": {
+			tag:  config.Tag{"genre": {"Podcast"}},
+			info: stubFields{"genre": "Rock"},
+			want: false,
+		},
+		"empty field is ignored": {
+			tag:  config.Tag{"genre": {"Podcast"}},
+			info: stubFields{"genre": ""},
+			want: false,
+		},
+		"empty tag never matches": {
+			tag:  config.Tag{},
+			info: stubFields{"genre": "Podcast"},
+			want: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.tag.Contains(tt.info); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+// TranscodeProfile configures on-the-fly transcoding of source files that
+// exceed a portable device's bitrate cap or use an unsupported codec.
+type TranscodeProfile struct {
+	// FFmpegPath overrides the ffmpeg binary discovered via exec.LookPath.
+	FFmpegPath string `json:"ffmpegPath"`
+
+	// MaxBitrateKbps is the highest source bitrate allowed before a file
+	// is transcoded. 0 disables the bitrate check.
+	MaxBitrateKbps int `json:"maxBitrateKbps"`
+
+	// TargetCodec is the codec to transcode to, e.g. "mp3", "opus", "aac".
+	// A source file already encoded with TargetCodec is only transcoded
+	// if it also exceeds MaxBitrateKbps.
+	TargetCodec string `json:"targetCodec"`
+
+	// SampleRate and Channels are available to CommandTemplate as "%ar"
+	// and "%ac". Leaving one at 0 still substitutes "0", so a
+	// CommandTemplate that references it needs it set.
+	SampleRate int `json:"sampleRate"`
+	Channels   int `json:"channels"`
+
+	// CommandTemplate is the ffmpeg invocation used to transcode, with
+	// "%s" standing in for the source path, "%bk" for "<MaxBitrateKbps>k",
+	// "%ar" for SampleRate and "%ac" for Channels, e.g.
+	// "ffmpeg -i %s -map 0:0 -b:a %bk -ar %ar -ac %ac -v 0 -f mp3 -".
+	// Empty picks a built-in default for TargetCodec (mp3/opus/aac are
+	// covered; any other codec falls back to the mp3 default, which will
+	// produce mp3 bytes regardless of what TargetCodec says — set
+	// CommandTemplate explicitly for codecs outside that set).
+	CommandTemplate string `json:"commandTemplate"`
+
+	// CacheDir is where transcoded output is cached, keyed by source hash
+	// and profile. Empty disables caching.
+	CacheDir string `json:"cacheDir"`
+}
@@ -0,0 +1,56 @@
+package filesync
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rebel-l/mp3sync/transcode"
+)
+
+// transcodeFile creates f.Dst's directory and re-encodes f.Src into it
+// via t.
+func transcodeFile(f File, t *transcode.Transcoder) error {
+	if err := os.MkdirAll(filepath.Dir(f.Dst), 0o750); err != nil {
+		return fmt.Errorf("failed to create destination folder for %s: %w", f.Dst, err)
+	}
+
+	if err := t.Transcode(f.Src, f.Dst); err != nil {
+		return fmt.Errorf("failed to transcode %s to %s: %w", f.Src, f.Dst, err)
+	}
+
+	return nil
+}
+
+// copyFile copies f.Src to f.Dst, creating any missing destination
+// directories.
+func copyFile(f File) error {
+	if err := os.MkdirAll(filepath.Dir(f.Dst), 0o750); err != nil {
+		return fmt.Errorf("failed to create destination folder for %s: %w", f.Dst, err)
+	}
+
+	src, err := os.Open(f.Src) // nolint: gosec
+	if err != nil {
+		return fmt.Errorf("failed to open source file %s: %w", f.Src, err)
+	}
+
+	defer func() {
+		_ = src.Close()
+	}()
+
+	dst, err := os.Create(f.Dst) // nolint: gosec
+	if err != nil {
+		return fmt.Errorf("failed to create destination file %s: %w", f.Dst, err)
+	}
+
+	defer func() {
+		_ = dst.Close()
+	}()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", f.Src, f.Dst, err)
+	}
+
+	return nil
+}
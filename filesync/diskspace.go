@@ -0,0 +1,39 @@
+package filesync
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/dustin/go-humanize"
+)
+
+// CalculateDiskSpace reports the free space on the destination volume, the
+// space files would need, and what would be left after syncing them.
+func CalculateDiskSpace(files []File, destination string) (DiskSpace, error) {
+	var stat syscall.Statfs_t
+
+	if err := syscall.Statfs(destination, &stat); err != nil {
+		return DiskSpace{}, fmt.Errorf("failed to determine free disk space on %s: %w", destination, err)
+	}
+
+	free := stat.Bavail * uint64(stat.Bsize) // nolint: unconvert
+
+	var needed uint64
+
+	for _, f := range files {
+		needed += uint64(f.Size)
+	}
+
+	var left string
+	if needed > free {
+		left = "-" + humanize.Bytes(needed-free)
+	} else {
+		left = humanize.Bytes(free - needed)
+	}
+
+	return DiskSpace{
+		Free:   humanize.Bytes(free),
+		Needed: humanize.Bytes(needed),
+		Left:   left,
+	}, nil
+}
@@ -0,0 +1,178 @@
+// Package filesync copies the files transform selected for syncing from
+// source to destination and reports on disk usage.
+package filesync
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rebel-l/mp3sync/manifest"
+	"github.com/rebel-l/mp3sync/progress"
+	"github.com/rebel-l/mp3sync/transcode"
+)
+
+// File is a single file to be synced from Src to Dst.
+type File struct {
+	Src  string
+	Dst  string
+	Size int64
+
+	// ModTime and Fingerprint are the source file's modification time and
+	// tag fingerprint. They are only used to populate the manifest entry
+	// recorded for this file once it has actually been synced.
+	ModTime     time.Time
+	Fingerprint string
+}
+
+// DiskSpace summarizes the disk space situation on the destination volume
+// for a set of Files to be synced.
+type DiskSpace struct {
+	Free   string
+	Needed string
+	Left   string
+}
+
+// Do syncs every File's Src to its Dst concurrently across workers
+// goroutines (runtime.NumCPU() if workers <= 0), returning one error per
+// file that failed. If t is non-nil, a file that exceeds t's profile
+// constraints is transcoded on the way to Dst instead of copied verbatim.
+// If m is non-nil, a manifest entry is recorded for a file only once it
+// has been successfully copied/transcoded to Dst, hashing Dst's actual
+// content rather than Src's. reporter (a progress.NoOp if nil) is
+// notified as files finish, and ctx cancellation stops feeding new files
+// to the pool.
+func Do(
+	ctx context.Context,
+	files []File,
+	t *transcode.Transcoder,
+	m *manifest.DB,
+	workers int,
+	reporter progress.Reporter,
+) []error {
+	if reporter == nil {
+		reporter = progress.NoOp{}
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	reporter.Started(len(files))
+	defer reporter.Finished()
+
+	type indexedFile struct {
+		index int
+		file  File
+	}
+
+	jobs := make(chan indexedFile)
+	results := make([]error, len(files))
+
+	var (
+		wg   sync.WaitGroup
+		done int32
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				results[j.index] = syncOne(j.file, t, m)
+
+				n := atomic.AddInt32(&done, 1)
+				reporter.Progress(int(n), j.file.Src)
+			}
+		}()
+	}
+
+feed:
+	for i, f := range files {
+		// select alone can't guarantee cancellation wins over a ready
+		// jobs<- send, since Go picks pseudo-randomly between ready
+		// cases: check ctx.Err() explicitly first so a cancelled ctx
+		// deterministically stops new files from being fed to the pool.
+		if ctx.Err() != nil {
+			for j := i; j < len(files); j++ {
+				results[j] = ctx.Err()
+			}
+
+			break feed
+		}
+
+		select {
+		case <-ctx.Done():
+			for j := i; j < len(files); j++ {
+				results[j] = ctx.Err()
+			}
+
+			break feed
+		case jobs <- indexedFile{index: i, file: f}:
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	var errs []error
+
+	for _, err := range results {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func syncOne(f File, t *transcode.Transcoder, m *manifest.DB) error {
+	if t != nil {
+		should, err := t.ShouldTranscode(f.Src)
+		if err != nil {
+			return err
+		}
+
+		if should {
+			if err := transcodeFile(f, t); err != nil {
+				return err
+			}
+
+			return recordManifest(m, f)
+		}
+	}
+
+	if err := copyFile(f); err != nil {
+		return err
+	}
+
+	return recordManifest(m, f)
+}
+
+// recordManifest stores f's manifest entry, hashing the just-written Dst
+// content so mp3sync verify can detect drift even for files a
+// TranscodeProfile intentionally re-encodes. A nil m is a no-op.
+func recordManifest(m *manifest.DB, f File) error {
+	if m == nil {
+		return nil
+	}
+
+	sum, err := manifest.HashFile(f.Dst)
+	if err != nil {
+		return err
+	}
+
+	return m.Put(manifest.Entry{
+		SourcePath:     f.Src,
+		DestPath:       f.Dst,
+		Size:           f.Size,
+		ModTime:        f.ModTime,
+		SHA256:         sum,
+		TagFingerprint: f.Fingerprint,
+		LastSyncedAt:   time.Now(),
+	})
+}
@@ -1,18 +1,25 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/c-bata/go-prompt"
 	"github.com/fatih/color"
 	"github.com/rebel-l/mp3sync/config"
 	"github.com/rebel-l/mp3sync/filesync"
-	"github.com/rebel-l/mp3sync/filter"
+	"github.com/rebel-l/mp3sync/manifest"
+	"github.com/rebel-l/mp3sync/mp3files"
+	"github.com/rebel-l/mp3sync/pathfilter"
+	"github.com/rebel-l/mp3sync/transcode"
 	"github.com/rebel-l/mp3sync/transform"
 )
 
@@ -26,12 +33,22 @@ var (
 	errPathNotExisting = errors.New("path does not exist")
 	errWriteLog        = errors.New("failed to write log file")
 	errAbortedByUser   = errors.New("aborted by user")
+	errNoManifest      = errors.New("manifestPath is not configured")
+	errDriftFound      = errors.New("drift found")
 	errFormat          = color.New(color.FgRed)
 	description        = color.New(color.FgGreen)  // nolint: gochecknoglobals
 	listFormat         = color.New(color.FgHiBlue) // nolint: gochecknoglobals
 )
 
+// dryRun is set via the -dry-run flag. When true, do prints the computed
+// destination for every file that would be synced instead of copying
+// anything.
+var dryRun bool // nolint: gochecknoglobals
+
 func main() {
+	flag.BoolVar(&dryRun, "dry-run", false, "print computed destinations instead of syncing files")
+	flag.Parse()
+
 	title := color.New(color.Bold, color.FgGreen)
 	_, _ = title.Println("MP3 sync started ...")
 	fmt.Println()
@@ -44,37 +61,53 @@ func main() {
 		return
 	}
 
-	_, _ = description.Printf("Source: %s\n", info.Sprint(conf.Source))
-	_, _ = description.Printf("Destination: %s\n", info.Sprint(conf.Destination))
+	transform.UseBackends(conf.Backends)
 
-	fmt.Println()
+	switch flag.Arg(0) {
+	case "verify":
+		err = runVerify(conf)
+	case "prune":
+		err = runPrune(conf)
+	default:
+		err = runSync(conf, info)
+	}
 
-	if err := do(conf); err != nil {
+	if err != nil {
 		fmt.Println()
 
 		_, _ = errFormat.Printf("MP3 sync finished with error: %v\n", err)
-	} else {
-		fmt.Println()
 
-		_, _ = title.Println("MP3 sync finished successful!")
+		return
 	}
+
+	fmt.Println()
+
+	_, _ = title.Println("MP3 sync finished successful!")
+}
+
+// runSync runs the normal filter/transform/sync flow.
+func runSync(conf *config.Config, info *color.Color) error {
+	_, _ = description.Printf("Source: %s\n", info.Sprint(conf.Source))
+	_, _ = description.Printf("Destination: %s\n", info.Sprint(conf.Destination))
+
+	fmt.Println()
+
+	return do(conf)
 }
 
 func do(conf *config.Config) error {
-	// 1. read file list from source (incl. filter) and destination (excl. filter)
-	sourceChannel := make(chan FileChannel)
-	destinationChannel := make(chan FileChannel)
-	go readFileList(sourceChannel, conf.Source, conf.Filter)
-	go readFileList(destinationChannel, conf.Destination, nil)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	sourceResult := <-sourceChannel
-	if sourceResult.Err != nil {
-		return sourceResult.Err
+	// 1. read file list from source (incl. filter) and destination (excl. filter)
+	sourceFiles, err := mp3files.Walk(conf.Source, conf.Destination, pathfilter.New(conf.PathFilter.Include, conf.PathFilter.Exclude))
+	if err != nil {
+		return err
 	}
 
-	destinationResult := <-destinationChannel
-	if destinationResult.Err != nil {
-		return destinationResult.Err
+	destinationFiles, err := mp3files.Walk(conf.Destination, "", pathfilter.New(nil, nil))
+	if err != nil {
+		return err
 	}
 
 	fmt.Println()
@@ -85,10 +118,26 @@ func do(conf *config.Config) error {
 	_, _ = description.Println("Filter & transform files to be synced ...")
 	start := time.Now()
 
-	wl, _ := conf.Filter.MP3Tag(filter.KeyWhitelist)
-	bl, _ := conf.Filter.MP3Tag(filter.KeyBlacklist)
+	wl, bl := conf.Whitelist, conf.Blacklist
+
+	var transformManifest *manifest.DB
+
+	if conf.ManifestPath != "" {
+		m, err := manifest.Open(conf.ManifestPath)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = m.Close()
+		}()
 
-	transformedSource, errs := transform.Do(sourceResult.Files, conf.Destination, conf.Source, wl, bl)
+		transformManifest = m
+	}
+
+	transformedSource, errs := transform.Do(
+		ctx, sourceFiles, conf.Destination, conf.Source, wl, bl, conf.Naming, conf.Transcode, transformManifest, conf.Workers, &cliReporter{},
+	)
 	if len(errs) > 0 {
 		globErr = true
 
@@ -97,11 +146,15 @@ func do(conf *config.Config) error {
 		}
 	}
 
-	duration(start, time.Now(), fmt.Sprintf("%d files filtered and transformed result in %d files", len(sourceResult.Files), len(transformedSource)))
+	duration(start, time.Now(), fmt.Sprintf("%d files filtered and transformed result in %d files", len(sourceFiles), len(transformedSource)))
 	fmt.Println()
 
 	// 3. diff file sizes + source / destination and set operations: copy / delete
-	syncFiles := diff(transformedSource, destinationResult.Files)
+	syncFiles := diff(transformedSource, destinationFiles, transformManifest)
+
+	if dryRun {
+		return printDryRun(syncFiles)
+	}
 
 	// 4. ask to list diff?
 	listDiff(syncFiles)
@@ -127,11 +180,20 @@ func do(conf *config.Config) error {
 		return errAbortedByUser
 	}
 
-	_, _ = description.Print("Sync files: ")
+	_, _ = description.Println("Sync files:")
 
 	start = time.Now()
 
-	errs = filesync.Do(syncFiles)
+	var transcoder *transcode.Transcoder
+
+	if conf.Transcode.TargetCodec != "" || conf.Transcode.MaxBitrateKbps > 0 {
+		transcoder, err = transcode.New(conf.Transcode)
+		if err != nil {
+			return err
+		}
+	}
+
+	errs = filesync.Do(ctx, syncFiles, transcoder, transformManifest, conf.Workers, &cliReporter{})
 
 	if len(errs) > 1 {
 		if errors.Is(errs[0], errAbortedByUser) {
@@ -162,6 +224,88 @@ func duration(start, finish time.Time, msg string) {
 	_, _ = description.Printf("%s in %s\n", msg, finish.Sub(start))
 }
 
+// cliReporter renders a progress.Reporter's updates as a single,
+// carriage-return-refreshed line.
+type cliReporter struct {
+	total int32
+}
+
+// Started implements progress.Reporter.
+func (r *cliReporter) Started(total int) {
+	atomic.StoreInt32(&r.total, int32(total))
+}
+
+// Progress implements progress.Reporter.
+func (r *cliReporter) Progress(done int, current string) {
+	_, _ = listFormat.Printf("\r%d/%d %s", done, atomic.LoadInt32(&r.total), current)
+}
+
+// Finished implements progress.Reporter.
+func (r *cliReporter) Finished() {
+	fmt.Println()
+}
+
+// diff compares transformed's computed destinations against destinationFiles
+// (a plain, untransformed walk of conf.Destination) and returns the subset
+// that still needs to be synced. If m is non-nil, a file is considered
+// already synced when the manifest has a fresh entry for it (size+mtime
+// unchanged, destination unchanged) — the same quick-check transformOne
+// uses — rather than by comparing raw source/destination byte sizes: a
+// transcoded file's destination size almost never equals its source size,
+// so a byte-size compare would re-transcode it on every single run. With
+// no manifest configured there is nothing to consult, so a file is synced
+// again whenever its destination is missing or a different size than the
+// source.
+func diff(transformed []transform.Result, destinationFiles []mp3files.File, m *manifest.DB) []filesync.File {
+	destSizes := make(map[string]int64, len(destinationFiles))
+	for _, f := range destinationFiles {
+		destSizes[f.Name] = f.Info.Size()
+	}
+
+	files := make([]filesync.File, 0, len(transformed))
+
+	for _, r := range transformed {
+		if m != nil {
+			if entry, err := m.Get(r.Src); err == nil && entry.DestPath == r.Dst && entry.Unchanged(r.Size, r.ModTime) {
+				continue
+			}
+		} else if size, ok := destSizes[r.Dst]; ok && size == r.Size {
+			continue
+		}
+
+		files = append(files, filesync.File{
+			Src:         r.Src,
+			Dst:         r.Dst,
+			Size:        r.Size,
+			ModTime:     r.ModTime,
+			Fingerprint: r.Fingerprint,
+		})
+	}
+
+	return files
+}
+
+// listDiff prints every file that diff selected for syncing.
+func listDiff(files []filesync.File) {
+	for _, f := range files {
+		_, _ = listFormat.Println(f.Dst)
+	}
+}
+
+// printDryRun prints the destination every file in syncFiles would be
+// synced to, without copying anything.
+func printDryRun(syncFiles []filesync.File) error {
+	_, _ = description.Println("Dry run, computed destinations:")
+
+	for _, f := range syncFiles {
+		_, _ = listFormat.Println(f.Dst)
+	}
+
+	_, _ = description.Printf("%d files would be synced\n", len(syncFiles))
+
+	return nil
+}
+
 func showAndLogErrors(errs []error) error {
 	logFileName, err := logErrors(errs)
 	if err != nil {
@@ -215,8 +359,77 @@ func logErrors(errs []error) (string, error) {
 	return logFileName, nil
 }
 
+// runVerify re-hashes every file the manifest knows about and reports any
+// destination that no longer matches what was recorded at sync time.
+func runVerify(conf *config.Config) error {
+	if conf.ManifestPath == "" {
+		return errNoManifest
+	}
+
+	m, err := manifest.Open(conf.ManifestPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = m.Close()
+	}()
+
+	drifts, err := manifest.Verify(m)
+	if err != nil {
+		return err
+	}
+
+	if len(drifts) == 0 {
+		_, _ = description.Println("no drift found")
+
+		return nil
+	}
+
+	for _, d := range drifts {
+		_, _ = errFormat.Printf("%s: %s\n", d.Entry.DestPath, d.Reason)
+	}
+
+	return fmt.Errorf("%w: %d files", errDriftFound, len(drifts))
+}
+
+// runPrune removes destination files whose manifest entry's source file no
+// longer exists, and drops those entries from the manifest.
+func runPrune(conf *config.Config) error {
+	if conf.ManifestPath == "" {
+		return errNoManifest
+	}
+
+	m, err := manifest.Open(conf.ManifestPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = m.Close()
+	}()
+
+	removed, err := manifest.Prune(m, sourceExists)
+	if err != nil {
+		return err
+	}
+
+	for _, dest := range removed {
+		_, _ = description.Printf("removed %s\n", dest)
+	}
+
+	_, _ = description.Printf("%d files removed\n", len(removed))
+
+	return nil
+}
+
+func sourceExists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
+
 // TODO:
-// 2. delete not matching files
-// 3. activate all linters
-// 4. Documentation / Badges: licence, goreport, issues, releases
-// 5. Tests / Badges: build, coverage
+// 2. activate all linters
+// 3. Documentation / Badges: licence, goreport, issues, releases
+// 4. Tests / Badges: build, coverage
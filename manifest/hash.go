@@ -0,0 +1,29 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// HashFile computes the hex-encoded SHA-256 digest of the file at path.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path) // nolint: gosec
+	if err != nil {
+		return "", fmt.Errorf("manifest: failed to open %s for hashing: %w", path, err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("manifest: failed to hash %s: %w", path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
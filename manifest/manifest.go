@@ -0,0 +1,159 @@
+// Package manifest persists a record of previously synced files so
+// subsequent runs can skip re-hashing and re-tagging unchanged files, and
+// so drift (edited-in-place or removed source files) can be detected.
+package manifest
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Entry is a single synced file recorded in the manifest.
+type Entry struct {
+	SourcePath     string
+	DestPath       string
+	Size           int64
+	ModTime        time.Time
+	SHA256         string
+	TagFingerprint string
+	LastSyncedAt   time.Time
+}
+
+// Unchanged reports whether size and modTime match e's recorded values.
+// This mirrors rsync's quick-check: a file is assumed unchanged if neither
+// its size nor its modification time has changed since it was last synced.
+func (e Entry) Unchanged(size int64, modTime time.Time) bool {
+	return e.Size == size && e.ModTime.Equal(modTime)
+}
+
+// ErrNotFound is returned by Get when no entry exists for a source path.
+var ErrNotFound = errors.New("manifest: entry not found")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS entries (
+	source_path     TEXT PRIMARY KEY,
+	dest_path       TEXT NOT NULL,
+	size            INTEGER NOT NULL,
+	mod_time        DATETIME NOT NULL,
+	sha256          TEXT NOT NULL,
+	tag_fingerprint TEXT NOT NULL,
+	last_synced_at  DATETIME NOT NULL
+);`
+
+// dsnParams is appended to every manifest DSN. A busy timeout makes
+// go-sqlite3 retry instead of failing outright with SQLITE_BUSY when a
+// second connection hits a lock, and WAL lets readers and a writer work
+// concurrently; SetMaxOpenConns(1) below still serializes writers, since
+// Get/Put are called on the same *DB from many worker-pool goroutines at
+// once.
+const dsnParams = "?_busy_timeout=5000&_journal_mode=WAL"
+
+// DB is a manifest backed by a SQLite database file.
+type DB struct {
+	conn *sql.DB
+}
+
+// Open opens (creating if needed) the manifest database at path and
+// ensures its schema exists.
+func Open(path string) (*DB, error) {
+	conn, err := sql.Open("sqlite3", path+dsnParams)
+	if err != nil {
+		return nil, fmt.Errorf("manifest: failed to open %s: %w", path, err)
+	}
+
+	conn.SetMaxOpenConns(1)
+
+	if _, err := conn.Exec(schema); err != nil {
+		return nil, fmt.Errorf("manifest: failed to migrate %s: %w", path, err)
+	}
+
+	return &DB{conn: conn}, nil
+}
+
+// Close closes the underlying database connection.
+func (db *DB) Close() error {
+	return db.conn.Close()
+}
+
+// Get returns the Entry recorded for sourcePath, or ErrNotFound.
+func (db *DB) Get(sourcePath string) (Entry, error) {
+	row := db.conn.QueryRow(
+		`SELECT source_path, dest_path, size, mod_time, sha256, tag_fingerprint, last_synced_at
+		 FROM entries WHERE source_path = ?`,
+		sourcePath,
+	)
+
+	var e Entry
+
+	if err := row.Scan(&e.SourcePath, &e.DestPath, &e.Size, &e.ModTime, &e.SHA256, &e.TagFingerprint, &e.LastSyncedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Entry{}, ErrNotFound
+		}
+
+		return Entry{}, fmt.Errorf("manifest: failed to read %s: %w", sourcePath, err)
+	}
+
+	return e, nil
+}
+
+// Put inserts or updates the Entry for e.SourcePath.
+func (db *DB) Put(e Entry) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO entries (source_path, dest_path, size, mod_time, sha256, tag_fingerprint, last_synced_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(source_path) DO UPDATE SET
+			dest_path = excluded.dest_path,
+			size = excluded.size,
+			mod_time = excluded.mod_time,
+			sha256 = excluded.sha256,
+			tag_fingerprint = excluded.tag_fingerprint,
+			last_synced_at = excluded.last_synced_at`,
+		e.SourcePath, e.DestPath, e.Size, e.ModTime, e.SHA256, e.TagFingerprint, e.LastSyncedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("manifest: failed to store %s: %w", e.SourcePath, err)
+	}
+
+	return nil
+}
+
+// Delete removes the Entry for sourcePath, if any.
+func (db *DB) Delete(sourcePath string) error {
+	if _, err := db.conn.Exec(`DELETE FROM entries WHERE source_path = ?`, sourcePath); err != nil {
+		return fmt.Errorf("manifest: failed to delete %s: %w", sourcePath, err)
+	}
+
+	return nil
+}
+
+// All returns every Entry currently recorded.
+func (db *DB) All() ([]Entry, error) {
+	rows, err := db.conn.Query(
+		`SELECT source_path, dest_path, size, mod_time, sha256, tag_fingerprint, last_synced_at FROM entries`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("manifest: failed to list entries: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []Entry
+
+	for rows.Next() {
+		var e Entry
+
+		if err := rows.Scan(&e.SourcePath, &e.DestPath, &e.Size, &e.ModTime, &e.SHA256, &e.TagFingerprint, &e.LastSyncedAt); err != nil {
+			return nil, fmt.Errorf("manifest: failed to scan entry: %w", err)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
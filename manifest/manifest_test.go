@@ -0,0 +1,118 @@
+package manifest_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rebel-l/mp3sync/manifest"
+)
+
+func TestEntry_Unchanged(t *testing.T) {
+	now := time.Now()
+	e := manifest.Entry{Size: 100, ModTime: now}
+
+	tests := map[string]struct {
+		size    int64
+		modTime time.Time
+		want    bool
+	}{
+		"same size and mtime":      {size: 100, modTime: now, want: true},
+		"different size":           {size: 101, modTime: now, want: false},
+		"different mtime":          {size: 100, modTime: now.Add(time.Second), want: false},
+		"different size and mtime": {size: 101, modTime: now.Add(time.Second), want: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := e.Unchanged(tt.size, tt.modTime); got != tt.want {
+				t.Errorf("Unchanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func openTestDB(t *testing.T) *manifest.DB {
+	t.Helper()
+
+	db, err := manifest.Open(filepath.Join(t.TempDir(), "manifest.sqlite"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db
+}
+
+func TestDB_PutGet(t *testing.T) {
+	db := openTestDB(t)
+
+	entry := manifest.Entry{
+		SourcePath:     "/src/a.mp3",
+		DestPath:       "/dst/a.mp3",
+		Size:           123,
+		ModTime:        time.Now().Truncate(time.Second),
+		SHA256:         "deadbeef",
+		TagFingerprint: "Artist|Album|Title||",
+		LastSyncedAt:   time.Now().Truncate(time.Second),
+	}
+
+	if err := db.Put(entry); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	got, err := db.Get(entry.SourcePath)
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+
+	if got.DestPath != entry.DestPath || got.SHA256 != entry.SHA256 {
+		t.Errorf("Get() = %+v, want %+v", got, entry)
+	}
+}
+
+func TestDB_Get_NotFound(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := db.Get("/does/not/exist.mp3"); err != manifest.ErrNotFound {
+		t.Errorf("Get() error = %v, want %v", err, manifest.ErrNotFound)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	db := openTestDB(t)
+
+	kept := manifest.Entry{SourcePath: "/src/keep.mp3", DestPath: filepath.Join(t.TempDir(), "keep.mp3")}
+	removedFile := filepath.Join(t.TempDir(), "gone.mp3")
+	removed := manifest.Entry{SourcePath: "/src/gone.mp3", DestPath: removedFile}
+
+	if err := db.Put(kept); err != nil {
+		t.Fatalf("Put(kept) failed: %v", err)
+	}
+
+	if err := db.Put(removed); err != nil {
+		t.Fatalf("Put(removed) failed: %v", err)
+	}
+
+	exists := map[string]bool{kept.SourcePath: true}
+
+	got, err := manifest.Prune(db, func(sourcePath string) bool { return exists[sourcePath] })
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+
+	if len(got) != 1 || got[0] != removed.DestPath {
+		t.Fatalf("Prune() removed = %v, want [%s]", got, removed.DestPath)
+	}
+
+	if _, err := db.Get(removed.SourcePath); err != manifest.ErrNotFound {
+		t.Errorf("Get(removed) error = %v, want ErrNotFound after prune", err)
+	}
+
+	if _, err := db.Get(kept.SourcePath); err != nil {
+		t.Errorf("Get(kept) failed after prune: %v", err)
+	}
+}
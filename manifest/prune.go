@@ -0,0 +1,36 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+)
+
+// Prune removes destination files whose source row's SourcePath no longer
+// passes sourceExists, and deletes the corresponding manifest rows. It
+// returns the DestPaths it removed.
+func Prune(db *DB, sourceExists func(sourcePath string) bool) ([]string, error) {
+	entries, err := db.All()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+
+	for _, e := range entries {
+		if sourceExists(e.SourcePath) {
+			continue
+		}
+
+		if err := os.Remove(e.DestPath); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("manifest: failed to remove %s: %w", e.DestPath, err)
+		}
+
+		if err := db.Delete(e.SourcePath); err != nil {
+			return removed, err
+		}
+
+		removed = append(removed, e.DestPath)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,36 @@
+package manifest
+
+import "fmt"
+
+// Drift describes a destination file that no longer matches its recorded
+// manifest Entry.
+type Drift struct {
+	Entry  Entry
+	Reason string
+}
+
+// Verify re-hashes every DestPath recorded in db and reports entries whose
+// current content no longer matches the recorded SHA256.
+func Verify(db *DB) ([]Drift, error) {
+	entries, err := db.All()
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+
+	for _, e := range entries {
+		sum, err := HashFile(e.DestPath)
+		if err != nil {
+			drifts = append(drifts, Drift{Entry: e, Reason: fmt.Sprintf("failed to hash: %v", err)})
+
+			continue
+		}
+
+		if sum != e.SHA256 {
+			drifts = append(drifts, Drift{Entry: e, Reason: "sha256 mismatch"})
+		}
+	}
+
+	return drifts, nil
+}
@@ -0,0 +1,14 @@
+// Package mp3files represents the files discovered while walking a source
+// or destination directory tree.
+package mp3files
+
+import "os"
+
+// File represents a single file discovered while walking a directory tree.
+type File struct {
+	// Name is the file's full path.
+	Name string
+
+	// Info is the os.FileInfo the filesystem returned for Name.
+	Info os.FileInfo
+}
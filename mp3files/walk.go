@@ -0,0 +1,103 @@
+package mp3files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rebel-l/mp3sync/pathfilter"
+)
+
+// Walk collects every file under root that pf does not skip, picking up a
+// pathfilter.IgnoreFileName file from each directory as it descends. A
+// directory's ignore patterns are scoped to its own subtree: they stop
+// applying as soon as the walk returns to a sibling directory. If
+// destination lives inside root (or root inside destination), the
+// overlapping subtree is skipped entirely so source and destination can
+// safely point at overlapping paths.
+func Walk(root, destination string, pf *pathfilter.Filter) ([]File, error) {
+	type scope struct {
+		dir                    string
+		includeLen, excludeLen int
+	}
+
+	rootIncludeLen, rootExcludeLen := pf.Mark()
+	if err := pf.AddIgnoreFile(filepath.Join(root, pathfilter.IgnoreFileName)); err != nil {
+		return nil, err
+	}
+
+	stack := []scope{{dir: root, includeLen: rootIncludeLen, excludeLen: rootExcludeLen}}
+
+	var files []File
+
+	err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == root {
+			return nil
+		}
+
+		if destination != "" && isInside(destination, path) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		// Pop every scope we have walked back out of, so a directory's
+		// ignore patterns don't leak into siblings visited afterwards.
+		for len(stack) > 1 && !isInside(stack[len(stack)-1].dir, path) {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			pf.Reset(top.includeLen, top.excludeLen)
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return fmt.Errorf("mp3files: failed to compute relative path for %s: %w", path, err)
+		}
+
+		if pf.Skip(rel, fi.IsDir()) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if fi.IsDir() {
+			includeLen, excludeLen := pf.Mark()
+			if err := pf.AddIgnoreFile(filepath.Join(path, pathfilter.IgnoreFileName)); err != nil {
+				return err
+			}
+
+			stack = append(stack, scope{dir: path, includeLen: includeLen, excludeLen: excludeLen})
+
+			return nil
+		}
+
+		files = append(files, File{Name: path, Info: fi})
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mp3files: failed to walk %s: %w", root, err)
+	}
+
+	return files, nil
+}
+
+// isInside reports whether path is destination itself or lives underneath
+// it.
+func isInside(destination, path string) bool {
+	rel, err := filepath.Rel(destination, path)
+	if err != nil {
+		return false
+	}
+
+	return rel == "." || !strings.HasPrefix(rel, "..")
+}
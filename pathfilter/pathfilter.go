@@ -0,0 +1,138 @@
+// Package pathfilter decides whether a path should be skipped while
+// walking a source tree, based on gitignore-style include/exclude
+// patterns.
+package pathfilter
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// IgnoreFileName is the name of the per-directory ignore file Filter picks
+// up while a tree is walked, gitignore-style.
+const IgnoreFileName = ".mp3syncignore"
+
+// Filter decides whether a relative path should be skipped.
+type Filter struct {
+	include []string
+	exclude []string
+}
+
+// New creates a Filter seeded with explicit include/exclude patterns, e.g.
+// from config.PathFilter. Patterns support "**", "!" negation (an exclude
+// pattern starting with "!" is added to include without the "!", like
+// passing it as Include) and directory-only matches ("foo/").
+func New(include, exclude []string) *Filter {
+	f := &Filter{include: append([]string(nil), include...)}
+
+	for _, p := range exclude {
+		if strings.HasPrefix(p, "!") {
+			f.include = append(f.include, strings.TrimPrefix(p, "!"))
+
+			continue
+		}
+
+		f.exclude = append(f.exclude, p)
+	}
+
+	return f
+}
+
+// AddIgnoreFile merges every pattern found in the ignore file at path into
+// f, treating lines starting with "!" as re-includes. A missing file is
+// not an error, so callers can call it for every directory unconditionally.
+func (f *Filter) AddIgnoreFile(path string) error {
+	file, err := os.Open(path) // nolint: gosec
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("pathfilter: failed to read %s: %w", path, err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			f.include = append(f.include, strings.TrimPrefix(line, "!"))
+
+			continue
+		}
+
+		f.exclude = append(f.exclude, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("pathfilter: failed to read %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Mark returns the current length of f's include/exclude pattern lists,
+// so a caller can later Reset back to this point once it has walked back
+// out of the directory whose ignore file it is about to add with
+// AddIgnoreFile. This lets Walk scope a directory's .mp3syncignore
+// patterns to that directory's own subtree instead of leaking them into
+// siblings walked afterwards.
+func (f *Filter) Mark() (int, int) {
+	return len(f.include), len(f.exclude)
+}
+
+// Reset truncates f's include/exclude pattern lists back to the lengths
+// returned by an earlier Mark, dropping everything appended since.
+func (f *Filter) Reset(includeLen, excludeLen int) {
+	f.include = f.include[:includeLen]
+	f.exclude = f.exclude[:excludeLen]
+}
+
+// Skip reports whether relPath (slash-separated, relative to the walk
+// root) should be skipped: it must match an exclude pattern and no include
+// (negated) pattern. An include pattern re-includes a path wherever it
+// appears relative to the exclude patterns.
+func (f *Filter) Skip(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(relPath)
+
+	if !matchAny(f.exclude, relPath, isDir) {
+		return false
+	}
+
+	return !matchAny(f.include, relPath, isDir)
+}
+
+func matchAny(patterns []string, relPath string, isDir bool) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "/") {
+			if !isDir {
+				continue
+			}
+
+			p = strings.TrimSuffix(p, "/")
+		}
+
+		if matched, _ := doublestar.Match(p, relPath); matched {
+			return true
+		}
+
+		// A pattern also covers everything below a directory it matches.
+		if matched, _ := doublestar.Match(p+"/**", relPath); matched {
+			return true
+		}
+	}
+
+	return false
+}
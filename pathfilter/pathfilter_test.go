@@ -0,0 +1,79 @@
+package pathfilter_test
+
+import (
+	"testing"
+
+	"github.com/rebel-l/mp3sync/pathfilter"
+)
+
+func TestFilter_Skip(t *testing.T) {
+	tests := map[string]struct {
+		include, exclude []string
+		path             string
+		isDir            bool
+		want             bool
+	}{
+		"no patterns never skips": {
+			path: "a.mp3",
+			want: false,
+		},
+		"exact exclude match": {
+			exclude: []string{"secret.mp3"},
+			path:    "secret.mp3",
+			want:    true,
+		},
+		"doublestar matches any depth": {
+			exclude: []string{"**/*.wav"},
+			path:    "a/b/c.wav",
+			want:    true,
+		},
+		"directory-only pattern skips the directory": {
+			exclude: []string{"Podcasts/"},
+			path:    "Podcasts",
+			isDir:   true,
+			want:    true,
+		},
+		"directory-only pattern does not match a file of the same name": {
+			exclude: []string{"Podcasts/"},
+			path:    "Podcasts",
+			isDir:   false,
+			want:    false,
+		},
+		"excluded directory implicitly excludes everything below it": {
+			exclude: []string{"Podcasts"},
+			path:    "Podcasts/episode1.mp3",
+			want:    true,
+		},
+		"negated include re-includes a file under an excluded tree": {
+			exclude: []string{"Podcasts/**"},
+			include: []string{"Podcasts/keep.mp3"},
+			path:    "Podcasts/keep.mp3",
+			want:    false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f := pathfilter.New(tt.include, tt.exclude)
+			if got := f.Skip(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Skip(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_MarkReset(t *testing.T) {
+	f := pathfilter.New([]string{"keep.mp3"}, []string{"a.mp3"})
+
+	includeLen, excludeLen := f.Mark()
+
+	if err := f.AddIgnoreFile("testdata/does-not-exist/.mp3syncignore"); err != nil {
+		t.Fatalf("AddIgnoreFile() failed for a missing file: %v", err)
+	}
+
+	f.Reset(includeLen, excludeLen)
+
+	if f.Skip("a.mp3", false) != true {
+		t.Error("Reset() dropped a pattern that predates the Mark()")
+	}
+}
@@ -0,0 +1,33 @@
+// Package progress reports on the progress of a long-running batch
+// operation, so callers such as the CLI can render a live progress bar
+// instead of a single "done" print.
+package progress
+
+// Reporter is notified as a batch operation proceeds. Progress may be
+// called concurrently from multiple workers; implementations must be safe
+// for concurrent use.
+type Reporter interface {
+	// Started is called once, before any work starts, with the total
+	// number of items to process.
+	Started(total int)
+
+	// Progress is called after each item finishes, with the number of
+	// items done so far and the item just processed.
+	Progress(done int, current string)
+
+	// Finished is called once, after every item has been processed.
+	Finished()
+}
+
+// NoOp is a Reporter that does nothing, used when a caller doesn't care
+// about progress.
+type NoOp struct{}
+
+// Started implements Reporter.
+func (NoOp) Started(int) {}
+
+// Progress implements Reporter.
+func (NoOp) Progress(int, string) {}
+
+// Finished implements Reporter.
+func (NoOp) Finished() {}
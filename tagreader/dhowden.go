@@ -0,0 +1,76 @@
+package tagreader
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dhowden/tag"
+)
+
+const dhowdenPriority = 100
+
+// DhowdenReader reads Info from FLAC, OGG, M4A and APE files using
+// github.com/dhowden/tag.
+type DhowdenReader struct{}
+
+// NewDhowdenReader creates a DhowdenReader.
+func NewDhowdenReader() *DhowdenReader {
+	return &DhowdenReader{}
+}
+
+// Read implements Reader.
+func (r *DhowdenReader) Read(path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("tagreader: dhowden: %w", err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	m, err := tag.ReadFrom(f)
+	if err != nil {
+		return Info{}, fmt.Errorf("tagreader: dhowden: %w", err)
+	}
+
+	track, _ := m.Track()
+	disc, _ := m.Disc()
+
+	return Info{
+		Artist:      m.Artist(),
+		Album:       m.Album(),
+		AlbumArtist: m.AlbumArtist(),
+		Year:        yearString(m.Year()),
+		Disc:        intString(disc),
+		Track:       intString(track),
+		Title:       m.Title(),
+		Genre:       m.Genre(),
+	}, nil
+}
+
+// Extensions implements Reader.
+func (r *DhowdenReader) Extensions() []string {
+	return []string{".flac", ".ogg", ".m4a", ".ape"}
+}
+
+// Priority implements Reader.
+func (r *DhowdenReader) Priority() int {
+	return dhowdenPriority
+}
+
+func yearString(year int) string {
+	if year == 0 {
+		return ""
+	}
+
+	return intString(year)
+}
+
+func intString(i int) string {
+	if i == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d", i)
+}
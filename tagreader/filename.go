@@ -0,0 +1,43 @@
+package tagreader
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// filenamePriority is deliberately the lowest of all backends.
+const filenamePriority = 0
+
+// FilenameReader is the fallback Reader used for files whose extension no
+// other registered Reader claims. It is not used when a format-specific
+// backend claims the extension but fails to read the file. It derives
+// Artist/Title from filenames of the shape "Artist - Title.ext".
+type FilenameReader struct{}
+
+// NewFilenameReader creates a FilenameReader.
+func NewFilenameReader() *FilenameReader {
+	return &FilenameReader{}
+}
+
+// Read implements Reader.
+func (r *FilenameReader) Read(path string) (Info, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	parts := strings.SplitN(name, " - ", 2)
+	if len(parts) == 2 {
+		return Info{Artist: strings.TrimSpace(parts[0]), Title: strings.TrimSpace(parts[1])}, nil
+	}
+
+	return Info{Title: strings.TrimSpace(name)}, nil
+}
+
+// Extensions implements Reader. FilenameReader claims the wildcard
+// extension so it is only chosen when nothing else matched.
+func (r *FilenameReader) Extensions() []string {
+	return []string{wildcardExtension}
+}
+
+// Priority implements Reader.
+func (r *FilenameReader) Priority() int {
+	return filenamePriority
+}
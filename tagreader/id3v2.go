@@ -0,0 +1,54 @@
+package tagreader
+
+import (
+	"fmt"
+
+	"github.com/bogem/id3v2/v2"
+)
+
+const (
+	id3v2FrameIDDisc  = "TPOS"
+	id3v2FrameIDTrack = "TRCK"
+	id3v2Priority     = 100
+)
+
+// ID3v2Reader reads Info from MP3 files using ID3v2 headers.
+type ID3v2Reader struct{}
+
+// NewID3v2Reader creates an ID3v2Reader.
+func NewID3v2Reader() *ID3v2Reader {
+	return &ID3v2Reader{}
+}
+
+// Read implements Reader.
+func (r *ID3v2Reader) Read(path string) (Info, error) {
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: true})
+	if err != nil {
+		return Info{}, fmt.Errorf("tagreader: id3v2: %w", err)
+	}
+
+	defer func() {
+		_ = tag.Close()
+	}()
+
+	return Info{
+		Artist:      tag.Artist(),
+		Album:       tag.Album(),
+		AlbumArtist: tag.GetTextFrame("TPE2").Text,
+		Year:        tag.Year(),
+		Disc:        tag.GetTextFrame(id3v2FrameIDDisc).Text,
+		Track:       tag.GetTextFrame(id3v2FrameIDTrack).Text,
+		Title:       tag.Title(),
+		Genre:       tag.Genre(),
+	}, nil
+}
+
+// Extensions implements Reader.
+func (r *ID3v2Reader) Extensions() []string {
+	return []string{".mp3"}
+}
+
+// Priority implements Reader.
+func (r *ID3v2Reader) Priority() int {
+	return id3v2Priority
+}
@@ -0,0 +1,51 @@
+//go:build taglib
+
+package tagreader
+
+import (
+	"fmt"
+
+	taglib "github.com/wtolson/go-taglib"
+)
+
+const taglibPriority = 90
+
+// TagLibReader reads Info using the TagLib C library bindings. It supports a
+// wide range of formats but requires libtag to be installed on the host, so
+// it is registered with a lower priority than format-specific backends.
+type TagLibReader struct{}
+
+// NewTagLibReader creates a TagLibReader.
+func NewTagLibReader() *TagLibReader {
+	return &TagLibReader{}
+}
+
+// Read implements Reader.
+func (r *TagLibReader) Read(path string) (Info, error) {
+	f, err := taglib.Read(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("tagreader: taglib: %w", err)
+	}
+
+	defer f.Close()
+
+	return Info{
+		Artist:     f.Artist(),
+		Album:      f.Album(),
+		Year:       intString(f.Year()),
+		Track:      intString(f.Track()),
+		Title:      f.Title(),
+		Genre:      f.Genre(),
+		DurationMs: int(f.Length().Milliseconds()),
+	}, nil
+}
+
+// Extensions implements Reader.
+func (r *TagLibReader) Extensions() []string {
+	return []string{".mp3", ".flac", ".ogg", ".m4a", ".wma", ".wav"}
+}
+
+// Priority implements Reader.
+func (r *TagLibReader) Priority() int {
+	return taglibPriority
+}
@@ -0,0 +1,36 @@
+//go:build !taglib
+
+package tagreader
+
+import "errors"
+
+// errTagLibUnavailable is returned by the stub TagLibReader built into a
+// plain `go build`. TagLibReader needs cgo plus a system libtag install, so
+// it is only compiled in when the binary is built with `-tags taglib`; a
+// default build gets this stub instead and never requires a C toolchain.
+var errTagLibUnavailable = errors.New("tagreader: taglib backend not built, rebuild with -tags taglib")
+
+// TagLibReader is the stub variant of the taglib backend used when the
+// taglib build tag is absent. It registers like the real backend but never
+// claims an extension, so Registry.For never selects it.
+type TagLibReader struct{}
+
+// NewTagLibReader creates a TagLibReader.
+func NewTagLibReader() *TagLibReader {
+	return &TagLibReader{}
+}
+
+// Read implements Reader.
+func (r *TagLibReader) Read(path string) (Info, error) {
+	return Info{}, errTagLibUnavailable
+}
+
+// Extensions implements Reader.
+func (r *TagLibReader) Extensions() []string {
+	return nil
+}
+
+// Priority implements Reader.
+func (r *TagLibReader) Priority() int {
+	return 0
+}
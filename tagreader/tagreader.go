@@ -0,0 +1,152 @@
+// Package tagreader provides a pluggable abstraction for reading audio
+// metadata from files, so callers are not tied to a single tag format or
+// library. Concrete backends (id3v2, taglib, dhowden/tag, ...) are
+// registered with a Registry, which picks the best backend for a given
+// file extension.
+package tagreader
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Info is the normalized set of metadata fields a Reader extracts from an
+// audio file, regardless of the underlying tag format.
+type Info struct {
+	Artist      string
+	Album       string
+	AlbumArtist string
+	Year        string
+	Disc        string
+	Track       string
+	Title       string
+	Genre       string
+	DurationMs  int
+}
+
+// Field returns the value of the named Info field (e.g. "artist",
+// "genre"), or "" if name is not a known field. It lets config.Tag match
+// against Info without tagreader depending on the config package.
+func (i Info) Field(name string) string {
+	switch name {
+	case "artist":
+		return i.Artist
+	case "album":
+		return i.Album
+	case "albumartist":
+		return i.AlbumArtist
+	case "year":
+		return i.Year
+	case "disc":
+		return i.Disc
+	case "track":
+		return i.Track
+	case "title":
+		return i.Title
+	case "genre":
+		return i.Genre
+	default:
+		return ""
+	}
+}
+
+// Reader reads Info from a single family of audio file formats.
+type Reader interface {
+	// Read extracts tag information from the file at path.
+	Read(path string) (Info, error)
+
+	// Extensions returns the lower-case file extensions (including the
+	// leading dot) this Reader can handle, e.g. []string{".mp3"}.
+	Extensions() []string
+
+	// Priority is used to break ties when several readers claim the same
+	// extension; the reader with the highest priority wins.
+	Priority() int
+}
+
+// ErrUnsupportedExtension is returned when no Reader is registered for a
+// file's extension.
+var ErrUnsupportedExtension = errors.New("tagreader: no reader registered for extension")
+
+type entry struct {
+	name   string
+	reader Reader
+}
+
+// Registry holds the set of registered Readers and resolves the best one
+// for a given file extension.
+type Registry struct {
+	entries []entry
+	byName  map[string]Reader
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]Reader)}
+}
+
+// Register adds reader to the registry under name. name is used by
+// config.Backends to disable or reorder backends; it has no meaning to the
+// Reader itself.
+func (r *Registry) Register(name string, reader Reader) {
+	r.entries = append(r.entries, entry{name: name, reader: reader})
+	r.byName[name] = reader
+}
+
+// Named returns the Reader registered under name, if any.
+func (r *Registry) Named(name string) (Reader, bool) {
+	reader, ok := r.byName[name]
+
+	return reader, ok
+}
+
+// wildcardExtension marks a Reader, such as the filename fallback, as
+// willing to handle any extension no other registered Reader claims.
+const wildcardExtension = "*"
+
+// For returns the highest-priority registered Reader that declares support
+// for ext (case-insensitive, including the leading dot). Ties are broken by
+// registration order, earlier wins. If no Reader explicitly supports ext,
+// a Reader registered for wildcardExtension is used instead, if any.
+func (r *Registry) For(ext string) (Reader, bool) {
+	ext = strings.ToLower(ext)
+
+	var best, wildcard Reader
+
+	for _, e := range r.entries {
+		for _, supported := range e.reader.Extensions() {
+			supported = strings.ToLower(supported)
+
+			switch {
+			case supported == ext:
+				if best == nil || e.reader.Priority() > best.Priority() {
+					best = e.reader
+				}
+			case supported == wildcardExtension:
+				if wildcard == nil || e.reader.Priority() > wildcard.Priority() {
+					wildcard = e.reader
+				}
+			}
+		}
+	}
+
+	if best != nil {
+		return best, true
+	}
+
+	return wildcard, wildcard != nil
+}
+
+// Read finds a Reader for path's extension and reads its tag Info.
+func (r *Registry) Read(path string) (Info, error) {
+	ext := filepath.Ext(path)
+
+	reader, ok := r.For(ext)
+	if !ok {
+		return Info{}, fmt.Errorf("%w: %s", ErrUnsupportedExtension, ext)
+	}
+
+	return reader.Read(path)
+}
@@ -0,0 +1,108 @@
+package tagreader_test
+
+import (
+	"testing"
+
+	"github.com/rebel-l/mp3sync/tagreader"
+)
+
+// stubReader is used by pointer everywhere below: comparing tagreader.Reader
+// interface values holding it with == needs a comparable dynamic type, and
+// a pointer identity comparison also sidesteps having to give stubReader a
+// valid Equal semantics for its slice field.
+type stubReader struct {
+	extensions []string
+	priority   int
+}
+
+func (s *stubReader) Read(string) (tagreader.Info, error) { return tagreader.Info{}, nil }
+func (s *stubReader) Extensions() []string                { return s.extensions }
+func (s *stubReader) Priority() int                       { return s.priority }
+
+func TestRegistry_For(t *testing.T) {
+	t.Run("picks the higher priority reader for an exact extension match", func(t *testing.T) {
+		reg := tagreader.NewRegistry()
+		low := &stubReader{extensions: []string{".mp3"}, priority: 1}
+		high := &stubReader{extensions: []string{".mp3"}, priority: 10}
+
+		reg.Register("low", low)
+		reg.Register("high", high)
+
+		got, ok := reg.For(".mp3")
+		if !ok {
+			t.Fatal("For() returned ok=false, want true")
+		}
+
+		if got != tagreader.Reader(high) {
+			t.Error("For() did not pick the higher-priority reader")
+		}
+	})
+
+	t.Run("earlier registration wins a priority tie", func(t *testing.T) {
+		reg := tagreader.NewRegistry()
+		first := &stubReader{extensions: []string{".mp3"}, priority: 5}
+		second := &stubReader{extensions: []string{".mp3"}, priority: 5}
+
+		reg.Register("first", first)
+		reg.Register("second", second)
+
+		got, ok := reg.For(".mp3")
+		if !ok {
+			t.Fatal("For() returned ok=false, want true")
+		}
+
+		if got != tagreader.Reader(first) {
+			t.Error("For() did not keep the earlier-registered reader on a tie")
+		}
+	})
+
+	t.Run("is case-insensitive on the extension", func(t *testing.T) {
+		reg := tagreader.NewRegistry()
+		reg.Register("mp3", &stubReader{extensions: []string{".mp3"}, priority: 1})
+
+		if _, ok := reg.For(".MP3"); !ok {
+			t.Error("For() = ok=false for a differently-cased extension, want true")
+		}
+	})
+
+	t.Run("falls back to a wildcard reader when nothing claims the extension", func(t *testing.T) {
+		reg := tagreader.NewRegistry()
+		fallback := &stubReader{extensions: []string{"*"}, priority: 0}
+		reg.Register("filename", fallback)
+
+		got, ok := reg.For(".wav")
+		if !ok {
+			t.Fatal("For() returned ok=false, want true")
+		}
+
+		if got != tagreader.Reader(fallback) {
+			t.Error("For() did not fall back to the wildcard reader")
+		}
+	})
+
+	t.Run("an exact match wins over a wildcard even at lower priority", func(t *testing.T) {
+		reg := tagreader.NewRegistry()
+		wildcard := &stubReader{extensions: []string{"*"}, priority: 100}
+		exact := &stubReader{extensions: []string{".mp3"}, priority: 1}
+
+		reg.Register("wildcard", wildcard)
+		reg.Register("exact", exact)
+
+		got, ok := reg.For(".mp3")
+		if !ok {
+			t.Fatal("For() returned ok=false, want true")
+		}
+
+		if got != tagreader.Reader(exact) {
+			t.Error("For() preferred the wildcard reader over an exact match")
+		}
+	})
+
+	t.Run("no reader registered", func(t *testing.T) {
+		reg := tagreader.NewRegistry()
+
+		if _, ok := reg.For(".mp3"); ok {
+			t.Error("For() = ok=true with no readers registered, want false")
+		}
+	})
+}
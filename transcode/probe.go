@@ -0,0 +1,58 @@
+package transcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// probeInfo is the subset of ffprobe's output Transcoder needs to decide
+// whether a file needs transcoding.
+type probeInfo struct {
+	Codec       string
+	BitrateKbps int
+}
+
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecName string `json:"codec_name"`
+		CodecType string `json:"codec_type"`
+	} `json:"streams"`
+	Format struct {
+		BitRate string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// probe shells out to ffprobe to read src's audio codec and bitrate.
+func probe(src string) (probeInfo, error) {
+	// nolint: gosec
+	out, err := exec.Command(
+		"ffprobe", "-v", "error", "-print_format", "json", "-show_format", "-show_streams", src,
+	).Output()
+	if err != nil {
+		return probeInfo{}, fmt.Errorf("%w: %s: %v", ErrProbe, src, err)
+	}
+
+	var parsed ffprobeOutput
+
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return probeInfo{}, fmt.Errorf("%w: %s: %v", ErrProbe, src, err)
+	}
+
+	info := probeInfo{}
+
+	for _, s := range parsed.Streams {
+		if s.CodecType == "audio" {
+			info.Codec = s.CodecName
+
+			break
+		}
+	}
+
+	if bps, err := strconv.Atoi(parsed.Format.BitRate); err == nil {
+		info.BitrateKbps = bps / 1000
+	}
+
+	return info, nil
+}
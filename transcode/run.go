@@ -0,0 +1,177 @@
+package transcode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bogem/id3v2/v2"
+)
+
+// Transcode re-encodes src into dst according to t's profile, preserving
+// ID3 tags and album art. If profile.CacheDir is set, a previous result
+// for the same source content and profile is reused instead of shelling
+// out to ffmpeg again.
+func (t *Transcoder) Transcode(src, dst string) error {
+	cachePath, err := t.cachePath(src)
+	if err != nil {
+		return err
+	}
+
+	if cachePath != "" && copyFile(cachePath, dst) == nil {
+		return nil
+	}
+
+	if err := t.run(src, dst); err != nil {
+		return err
+	}
+
+	if err := copyTags(src, dst); err != nil {
+		return err
+	}
+
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0o750); err == nil {
+			_ = copyFile(dst, cachePath)
+		}
+	}
+
+	return nil
+}
+
+func (t *Transcoder) run(src, dst string) error {
+	argv := t.command(src)
+
+	out, err := os.Create(dst) // nolint: gosec
+	if err != nil {
+		return fmt.Errorf("%w: failed to create %s: %v", ErrTranscode, dst, err)
+	}
+
+	defer func() {
+		_ = out.Close()
+	}()
+
+	cmd := exec.Command(argv[0], argv[1:]...) // nolint: gosec
+	cmd.Stdout = out
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrTranscode, src, err)
+	}
+
+	return nil
+}
+
+// cachePath returns where Transcode should look for/store a cached result
+// for src under the current profile, or "" if caching is disabled.
+func (t *Transcoder) cachePath(src string) (string, error) {
+	if t.profile.CacheDir == "" {
+		return "", nil
+	}
+
+	sum, err := hashFile(src)
+	if err != nil {
+		return "", err
+	}
+
+	fingerprint := fmt.Sprintf(
+		"%s-%d-%d-%d-%s",
+		t.profile.TargetCodec, t.profile.MaxBitrateKbps, t.profile.SampleRate, t.profile.Channels, t.profile.CommandTemplate,
+	)
+
+	key := sha256.Sum256([]byte(sum + fingerprint))
+
+	return filepath.Join(t.profile.CacheDir, hex.EncodeToString(key[:])), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path) // nolint: gosec
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrProbe, path, err)
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrProbe, path, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) // nolint: gosec
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = in.Close()
+	}()
+
+	out, err := os.Create(dst) // nolint: gosec
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = out.Close()
+	}()
+
+	_, err = io.Copy(out, in)
+
+	return err
+}
+
+// copyTags copies ID3v2 tags and attached pictures from src to dst. The
+// default CommandTemplate maps only the audio stream through ffmpeg, so
+// re-encoded files otherwise come out untagged.
+func copyTags(src, dst string) error {
+	srcTag, err := id3v2.Open(src, id3v2.Options{Parse: true})
+	if err != nil {
+		return fmt.Errorf("%w: failed to read tags from %s: %v", ErrTranscode, src, err)
+	}
+
+	defer func() {
+		_ = srcTag.Close()
+	}()
+
+	dstTag, err := id3v2.Open(dst, id3v2.Options{Parse: true})
+	if err != nil {
+		return fmt.Errorf("%w: failed to open %s for tagging: %v", ErrTranscode, dst, err)
+	}
+
+	defer func() {
+		_ = dstTag.Close()
+	}()
+
+	dstTag.SetArtist(srcTag.Artist())
+	dstTag.SetAlbum(srcTag.Album())
+	dstTag.SetTitle(srcTag.Title())
+	dstTag.SetYear(srcTag.Year())
+	dstTag.SetGenre(srcTag.Genre())
+
+	for _, id := range []string{"TPE2", "TPOS", "TRCK"} {
+		if f := srcTag.GetTextFrame(id); f.Text != "" {
+			dstTag.AddTextFrame(id, srcTag.DefaultEncoding(), f.Text)
+		}
+	}
+
+	for _, pic := range srcTag.GetFrames(srcTag.CommonID("Attached picture")) {
+		if p, ok := pic.(id3v2.PictureFrame); ok {
+			dstTag.AddAttachedPicture(p)
+		}
+	}
+
+	if err := dstTag.Save(); err != nil {
+		return fmt.Errorf("%w: failed to save tags to %s: %v", ErrTranscode, dst, err)
+	}
+
+	return nil
+}
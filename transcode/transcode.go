@@ -0,0 +1,151 @@
+// Package transcode re-encodes audio files that exceed a portable device's
+// bitrate or codec constraints, by shelling out to ffmpeg/ffprobe.
+package transcode
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/rebel-l/mp3sync/config"
+)
+
+const defaultTargetCodec = "mp3"
+
+// defaultCommandTemplates gives each supported TargetCodec its own default
+// ffmpeg invocation, keyed by the muxer/format ffmpeg needs to actually
+// produce that codec's bytes on stdout — a single hardcoded "-f mp3"
+// default would silently mislabel a non-mp3 TargetCodec: the destination
+// extension and ShouldTranscode's codec check would say "opus" while the
+// piped-out bytes stayed mp3.
+var defaultCommandTemplates = map[string]string{ // nolint: gochecknoglobals
+	"mp3":  "ffmpeg -i %s -map 0:0 -b:a %bk -v 0 -f mp3 -",
+	"opus": "ffmpeg -i %s -map 0:0 -b:a %bk -v 0 -f opus -",
+	"aac":  "ffmpeg -i %s -map 0:0 -b:a %bk -v 0 -f adts -",
+}
+
+// defaultCommandTemplate returns the default CommandTemplate for codec,
+// falling back to defaultTargetCodec's for an unrecognized or empty codec.
+func defaultCommandTemplate(codec string) string {
+	if tpl, ok := defaultCommandTemplates[strings.ToLower(codec)]; ok {
+		return tpl
+	}
+
+	return defaultCommandTemplates[defaultTargetCodec]
+}
+
+// codecExtensions maps a TargetCodec to the destination file extension
+// (including the dot) a file re-encoded to it should have.
+var codecExtensions = map[string]string{ // nolint: gochecknoglobals
+	"mp3":  ".mp3",
+	"opus": ".opus",
+	"aac":  ".aac",
+}
+
+// Extension returns the destination file extension for codec, so a caller
+// naming a file that an active TranscodeProfile targeting codec is about
+// to (re-)encode doesn't keep the source file's original extension. An
+// unrecognized codec falls back to ".<codec>".
+func Extension(codec string) string {
+	if ext, ok := codecExtensions[strings.ToLower(codec)]; ok {
+		return ext
+	}
+
+	return "." + strings.ToLower(codec)
+}
+
+var (
+	// ErrFFmpegNotFound is returned by New when no ffmpeg binary could be
+	// resolved.
+	ErrFFmpegNotFound = errors.New("transcode: ffmpeg binary not found")
+
+	// ErrProbe is returned when ffprobe fails to inspect a source file.
+	ErrProbe = errors.New("transcode: failed to probe source file")
+
+	// ErrTranscode is returned when the ffmpeg re-encode itself fails.
+	ErrTranscode = errors.New("transcode: ffmpeg failed")
+)
+
+// Transcoder re-encodes files via ffmpeg according to a
+// config.TranscodeProfile.
+type Transcoder struct {
+	profile    config.TranscodeProfile
+	ffmpegPath string
+}
+
+// New resolves the ffmpeg binary (profile.FFmpegPath, or exec.LookPath if
+// unset) and returns a Transcoder for profile.
+func New(profile config.TranscodeProfile) (*Transcoder, error) {
+	path := profile.FFmpegPath
+	if path == "" {
+		resolved, err := exec.LookPath("ffmpeg")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFFmpegNotFound, err)
+		}
+
+		path = resolved
+	}
+
+	return &Transcoder{profile: profile, ffmpegPath: path}, nil
+}
+
+// ShouldTranscode reports whether src exceeds the profile's bitrate cap or
+// uses a codec other than the target one, based on ffprobe's output.
+func (t *Transcoder) ShouldTranscode(src string) (bool, error) {
+	info, err := probe(src)
+	if err != nil {
+		return false, err
+	}
+
+	target := t.profile.TargetCodec
+	if target == "" {
+		target = defaultTargetCodec
+	}
+
+	if info.Codec != "" && !strings.EqualFold(info.Codec, target) {
+		return true, nil
+	}
+
+	return t.profile.MaxBitrateKbps > 0 && info.BitrateKbps > t.profile.MaxBitrateKbps, nil
+}
+
+// command builds the argv for the profile's CommandTemplate against src,
+// substituting "%s" with src, "%bk" with "<MaxBitrateKbps>k", "%ar" with
+// SampleRate and "%ac" with Channels. The template is tokenized on
+// whitespace first and substitution happens per already-split token, so a
+// src path containing spaces stays a single argv entry instead of being
+// torn apart by a substitute-then-split on the whole command line.
+func (t *Transcoder) command(src string) []string {
+	tpl := t.profile.CommandTemplate
+	if tpl == "" {
+		tpl = defaultCommandTemplate(t.profile.TargetCodec)
+	}
+
+	replacements := [][2]string{
+		{"%s", src},
+		{"%bk", strconv.Itoa(t.profile.MaxBitrateKbps) + "k"},
+		{"%ar", strconv.Itoa(t.profile.SampleRate)},
+		{"%ac", strconv.Itoa(t.profile.Channels)},
+	}
+
+	tokens := strings.Fields(tpl)
+	argv := make([]string, len(tokens))
+
+	for i, tok := range tokens {
+		if i == 0 {
+			argv[i] = t.ffmpegPath
+
+			continue
+		}
+
+		for _, r := range replacements {
+			tok = strings.ReplaceAll(tok, r[0], r[1])
+		}
+
+		argv[i] = tok
+	}
+
+	return argv
+}
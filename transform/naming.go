@@ -0,0 +1,203 @@
+package transform
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/rebel-l/mp3sync/config"
+	"github.com/rebel-l/mp3sync/tagreader"
+)
+
+const (
+	unknownArtist = "Unknown Artist"
+	unknownTitle  = "Unknown Title"
+	numericFolder = "#"
+
+	// defaultNamingTemplate follows the historic, hard-coded naming
+	// scheme: "Artist - Album (Year) - Disc - Track - Title.ext". Album,
+	// Year, Disc and Track only appear when set; Artist and Title always
+	// appear, falling back to unknownArtist and unknownTitle.
+	defaultNamingTemplate = `{{.Artist}}` +
+		`{{if .Album}} - {{.Album}}{{end}}` +
+		`{{if .Year}} ({{.Year}}){{end}}` +
+		`{{if .Disc}} - {{.Disc}}{{end}}` +
+		`{{if .Track}} - {{printf "%02d" .Track}}{{end}}` +
+		` - {{.Title}}{{.Ext}}`
+
+	// defaultFolderTemplate groups files into single-letter A-Z/#
+	// subfolders by album artist, replacing the old scheme of grouping by
+	// the first letter of the file's path under the source directory.
+	defaultFolderTemplate = `{{firstLetter .AlbumArtist}}`
+)
+
+// templateData is the value NamingTemplate and FolderTemplate are executed
+// against.
+type templateData struct {
+	Artist      string
+	Album       string
+	AlbumArtist string
+	Year        string
+	Disc        string
+	Track       int
+	Title       string
+	Genre       string
+	Ext         string
+}
+
+// buildTemplateData normalizes info into templateData, applying the
+// AlbumArtist -> Artist -> "Unknown Artist" fallback chain (and the
+// equivalent single-step fallback for Artist and Title).
+func buildTemplateData(info tagreader.Info, ext string) templateData {
+	artist := info.Artist
+	if artist == "" {
+		artist = unknownArtist
+	}
+
+	albumArtist := info.AlbumArtist
+	if albumArtist == "" {
+		albumArtist = info.Artist
+	}
+
+	if albumArtist == "" {
+		albumArtist = unknownArtist
+	}
+
+	title := info.Title
+	if title == "" {
+		title = unknownTitle
+	}
+
+	track, _ := strconv.Atoi(info.Track)
+
+	return templateData{
+		Artist:      artist,
+		Album:       info.Album,
+		AlbumArtist: albumArtist,
+		Year:        info.Year,
+		Disc:        info.Disc,
+		Track:       track,
+		Title:       title,
+		Genre:       info.Genre,
+		Ext:         ext,
+	}
+}
+
+// renderName executes naming.NamingTemplate (or defaultNamingTemplate)
+// against data to produce the destination file name.
+func renderName(data templateData, naming config.Naming) (string, error) {
+	tpl := naming.NamingTemplate
+	if tpl == "" {
+		tpl = defaultNamingTemplate
+	}
+
+	name, err := renderTemplate("name", tpl, data, naming.SanitizeMap)
+	if err != nil {
+		return "", err
+	}
+
+	// Characters unsafe for the destination filesystem are always
+	// stripped, even for custom templates that don't call {{sanitize}}
+	// themselves.
+	return sanitize(name, naming.SanitizeMap), nil
+}
+
+// renderFolder executes naming.FolderTemplate (or defaultFolderTemplate)
+// against data to produce the destination subfolder.
+func renderFolder(data templateData, naming config.Naming) (string, error) {
+	tpl := naming.FolderTemplate
+	if tpl == "" {
+		tpl = defaultFolderTemplate
+	}
+
+	folder, err := renderTemplate("folder", tpl, data, naming.SanitizeMap)
+	if err != nil {
+		return "", err
+	}
+
+	// Sanitize each path segment individually rather than the joined
+	// result: a blanket sanitize would hit the "/" separators the
+	// template itself introduced between segments (defaultSanitizeMap
+	// strips "/" entirely), collapsing a multi-level folder into one.
+	segments := strings.Split(folder, "/")
+	for i, segment := range segments {
+		segments[i] = sanitize(segment, naming.SanitizeMap)
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+func renderTemplate(name, tpl string, data templateData, sanitizeMap map[string]string) (string, error) {
+	t, err := template.New(name).Funcs(templateFuncs(sanitizeMap)).Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+
+	if err := t.Execute(&out, data); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+func templateFuncs(sanitizeMap map[string]string) template.FuncMap {
+	return template.FuncMap{
+		"firstLetter": firstLetter,
+		"sanitize": func(s string) string {
+			return sanitize(s, sanitizeMap)
+		},
+	}
+}
+
+// firstLetter returns the upper-cased first letter of s, or numericFolder
+// if s is empty or doesn't start with a letter.
+func firstLetter(s string) string {
+	if s == "" {
+		return numericFolder
+	}
+
+	letter := strings.ToUpper(s[0:1])
+
+	if matched, _ := regexp.MatchString("[A-Z]", letter); !matched {
+		return numericFolder
+	}
+
+	return letter
+}
+
+// sanitize replaces characters in s that are unsafe on the destination
+// filesystem, using replacements if set or defaultSanitizeMap otherwise.
+func sanitize(s string, replacements map[string]string) string {
+	if len(replacements) == 0 {
+		replacements = defaultSanitizeMap()
+	}
+
+	for from, to := range replacements {
+		s = strings.ReplaceAll(s, from, to)
+	}
+
+	return s
+}
+
+// defaultSanitizeMap is the historic hard-coded replaceChars table, tuned
+// for FAT32/exFAT/NTFS.
+func defaultSanitizeMap() map[string]string {
+	return map[string]string{
+		":":  ";",
+		"\\": "",
+		"/":  "",
+		"?":  "¿",
+		"\"": ",",
+		"'":  ",",
+		"*":  "x",
+		"+":  "x",
+		"[":  "(",
+		"]":  ")",
+		">":  "-",
+		"<":  "-",
+		"|":  "-",
+	}
+}
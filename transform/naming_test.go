@@ -0,0 +1,83 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/rebel-l/mp3sync/tagreader"
+)
+
+func TestBuildTemplateData(t *testing.T) {
+	tests := map[string]struct {
+		info tagreader.Info
+		want templateData
+	}{
+		"artist, album artist, title and track set": {
+			info: tagreader.Info{Artist: "Artist", AlbumArtist: "Album Artist", Title: "Title", Track: "3"},
+			want: templateData{Artist: "Artist", AlbumArtist: "Album Artist", Title: "Title", Track: 3, Ext: ".mp3"},
+		},
+		"album artist falls back to artist": {
+			info: tagreader.Info{Artist: "Artist", Title: "Title"},
+			want: templateData{Artist: "Artist", AlbumArtist: "Artist", Title: "Title", Ext: ".mp3"},
+		},
+		"album artist falls back to unknown when nothing is set": {
+			info: tagreader.Info{Title: "Title"},
+			want: templateData{Artist: unknownArtist, AlbumArtist: unknownArtist, Title: "Title", Ext: ".mp3"},
+		},
+		"title falls back to unknown": {
+			info: tagreader.Info{Artist: "Artist"},
+			want: templateData{Artist: "Artist", AlbumArtist: "Artist", Title: unknownTitle, Ext: ".mp3"},
+		},
+		"non-numeric track is dropped rather than erroring": {
+			info: tagreader.Info{Artist: "Artist", Title: "Title", Track: "n/a"},
+			want: templateData{Artist: "Artist", AlbumArtist: "Artist", Title: "Title", Track: 0, Ext: ".mp3"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := buildTemplateData(tt.info, ".mp3"); got != tt.want {
+				t.Errorf("buildTemplateData() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstLetter(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want string
+	}{
+		"letter":        {in: "queen", want: "Q"},
+		"lower-cased":   {in: "abba", want: "A"},
+		"empty":         {in: "", want: numericFolder},
+		"leading digit": {in: "311", want: numericFolder},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := firstLetter(tt.in); got != tt.want {
+				t.Errorf("firstLetter(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	t.Run("default map replaces filesystem-unsafe characters", func(t *testing.T) {
+		got := sanitize(`a:b/c*d`, nil)
+		want := "a;bcxd"
+
+		if got != want {
+			t.Errorf("sanitize() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("custom map overrides the default one entirely", func(t *testing.T) {
+		got := sanitize("a:b", map[string]string{":": "-"})
+		want := "a-b"
+
+		if got != want {
+			t.Errorf("sanitize() = %q, want %q", got, want)
+		}
+	})
+}
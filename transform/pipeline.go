@@ -0,0 +1,137 @@
+package transform
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rebel-l/mp3sync/config"
+	"github.com/rebel-l/mp3sync/manifest"
+	"github.com/rebel-l/mp3sync/mp3files"
+	"github.com/rebel-l/mp3sync/progress"
+)
+
+// Result is the outcome of transforming a single source file. Size and
+// ModTime are the source file's own stat values and Fingerprint
+// identifies its current tag content; filesync carries all three forward
+// so it can record a manifest entry once (and only once) Dst has actually
+// been written.
+type Result struct {
+	Src         string
+	Dst         string
+	Size        int64
+	ModTime     time.Time
+	Fingerprint string
+	Err         error
+}
+
+type job struct {
+	index int
+	file  mp3files.File
+}
+
+// Do transforms every file in files concurrently across workers goroutines
+// (runtime.NumCPU() if workers <= 0), reporting progress to reporter (a
+// progress.NoOp if nil) and stopping early if ctx is cancelled. It returns
+// a Result for every file that passed whiteList/blackList (files it
+// skipped are simply absent) and every error encountered, both in the
+// same order as files.
+func Do(
+	ctx context.Context,
+	files []mp3files.File,
+	destination, source string,
+	whiteList, blackList config.Tag,
+	naming config.Naming,
+	transcodeProfile config.TranscodeProfile,
+	m *manifest.DB,
+	workers int,
+	reporter progress.Reporter,
+) ([]Result, []error) {
+	if reporter == nil {
+		reporter = progress.NoOp{}
+	}
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	reporter.Started(len(files))
+	defer reporter.Finished()
+
+	jobs := make(chan job)
+	results := make([]Result, len(files))
+
+	var (
+		wg   sync.WaitGroup
+		done int32
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				dst, fingerprint, err := transformOne(destination, source, j.file, whiteList, blackList, naming, transcodeProfile, m)
+				results[j.index] = Result{
+					Src:         j.file.Name,
+					Dst:         dst,
+					Size:        j.file.Info.Size(),
+					ModTime:     j.file.Info.ModTime(),
+					Fingerprint: fingerprint,
+					Err:         err,
+				}
+
+				n := atomic.AddInt32(&done, 1)
+				reporter.Progress(int(n), j.file.Name)
+			}
+		}()
+	}
+
+feed:
+	for i, f := range files {
+		// select alone can't guarantee cancellation wins over a ready
+		// jobs<- send, since Go picks pseudo-randomly between ready
+		// cases: check ctx.Err() explicitly first so a cancelled ctx
+		// deterministically stops new files from being fed to the pool.
+		if ctx.Err() != nil {
+			for j := i; j < len(files); j++ {
+				results[j] = Result{Src: files[j].Name, Err: ctx.Err()}
+			}
+
+			break feed
+		}
+
+		select {
+		case <-ctx.Done():
+			for j := i; j < len(files); j++ {
+				results[j] = Result{Src: files[j].Name, Err: ctx.Err()}
+			}
+
+			break feed
+		case jobs <- job{index: i, file: f}:
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	var (
+		selected []Result
+		errs     []error
+	)
+
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			errs = append(errs, r.Err)
+		case r.Dst != "":
+			selected = append(selected, r)
+		}
+	}
+
+	return selected, errs
+}
@@ -3,111 +3,162 @@ package transform
 import (
 	"errors"
 	"fmt"
-	"github.com/bogem/id3v2/v2"
-	"github.com/rebel-l/mp3sync/config"
-	"os"
 	"path/filepath"
-	"regexp"
-	"strings"
 
+	"github.com/rebel-l/mp3sync/config"
+	"github.com/rebel-l/mp3sync/manifest"
 	"github.com/rebel-l/mp3sync/mp3files"
+	"github.com/rebel-l/mp3sync/tagreader"
+	"github.com/rebel-l/mp3sync/transcode"
 )
 
-const (
-	defaultSubfolder = "default"
-	numericSubfolder = "#"
-	frameIDDisk      = "TPOS"
-	frameIDTrack     = "TRCK"
-)
+// backendOrder is the built-in registration order of tagreader backends,
+// used whenever config.Backends.Order is empty.
+var backendOrder = []string{"id3v2", "taglib", "tag", "filename"} // nolint: gochecknoglobals
 
 var ErrParseTag = errors.New("failed to parse mp3 tag")
 
-func Do(destination string, source string, f mp3files.File, whiteList config.Tag, blackList config.Tag) (string, error) {
-	tag, err := loadTag(f) // TODO: should be outside of this package as it is not part of transformer
-	if err != nil {
-		return "", fmt.Errorf("%w from %s: %v", ErrParseTag, f.Name, err)
-	}
+// Registry is the tagreader.Registry loadTag reads from. It defaults to all
+// built-in backends in their built-in order; call UseBackends to apply
+// config.Backends on top of it.
+var Registry = newDefaultRegistry() // nolint: gochecknoglobals
 
-	if (len(whiteList) > 0 && !whiteList.Contains(tag)) || (len(blackList) > 0 && blackList.Contains(tag)) {
-		return "", nil
-	}
+// newDefaultRegistry registers every built-in tagreader backend under its
+// backendOrder name.
+func newDefaultRegistry() *tagreader.Registry {
+	available := builtinBackends()
 
-	name, err := getFileName(tag, filepath.Ext(f.Info.Name()))
-	if err != nil {
-		return "", fmt.Errorf("%w from %s: %v", ErrParseTag, f.Name, err)
+	reg := tagreader.NewRegistry()
+	for _, name := range backendOrder {
+		reg.Register(name, available[name])
 	}
 
-	return filepath.Join(destination, getSubFolder(f.Name, source), name), nil
+	return reg
 }
 
-func getSubFolder(fileName string, source string) string {
-	subFolder := defaultSubfolder
+func builtinBackends() map[string]tagreader.Reader {
+	return map[string]tagreader.Reader{
+		"id3v2":    tagreader.NewID3v2Reader(),
+		"taglib":   tagreader.NewTagLibReader(),
+		"tag":      tagreader.NewDhowdenReader(),
+		"filename": tagreader.NewFilenameReader(),
+	}
+}
+
+// UseBackends rebuilds Registry honouring cfg: disabled backends are
+// dropped and cfg.Order, if set, overrides backendOrder, with backends it
+// does not list registered after it in backendOrder. It should be
+// called once after loading the config, before Do is used.
+func UseBackends(cfg config.Backends) {
+	order := cfg.Order
+	if len(order) == 0 {
+		order = backendOrder
+	}
 
-	source = strings.Replace(fileName, source+string(os.PathSeparator), "", 1)
+	available := builtinBackends()
 
-	parts := strings.Split(source, string(os.PathSeparator))
+	reg := tagreader.NewRegistry()
 
-	if len(parts) > 0 {
-		subFolder = strings.ToUpper(string(parts[0][0]))
+	for _, name := range order {
+		if containsName(cfg.Disabled, name) {
+			continue
+		}
+
+		if backend, ok := available[name]; ok {
+			reg.Register(name, backend)
+		}
 	}
 
-	match, _ := regexp.MatchString("[A-Z]", subFolder)
-	if !match {
-		subFolder = numericSubfolder
+	// Backends missing from cfg.Order follow, in backendOrder.
+	for _, name := range backendOrder {
+		if containsName(order, name) || containsName(cfg.Disabled, name) {
+			continue
+		}
+
+		reg.Register(name, available[name])
 	}
 
-	return subFolder
+	Registry = reg
 }
 
-func getFileName(tag *id3v2.Tag, ext string) (string, error) {
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
 
-	name := tag.Artist()
+	return false
+}
 
-	if tag.Album() != "" {
-		name += " - " + tag.Album()
+// transformOne computes the destination path and tag fingerprint for f,
+// skipping it (returning "" for both) if whiteList or blackList rule it
+// out. If m is non-nil, transformOne consults it first: a file whose size
+// and modification time are unchanged since the last sync (rsync's
+// quick-check) reuses its recorded destination and fingerprint without
+// parsing tags again. transformOne never writes to m itself — the caller
+// only knows once the real copy/transcode succeeds, so recording the
+// manifest entry (and hashing the actual destination content) is
+// filesync's job, not this one's. transcodeProfile's TargetCodec, if set,
+// replaces the source file's own extension in the computed destination
+// name: any file synced under an active transcode profile either already
+// matches TargetCodec or is about to be re-encoded to it by filesync, so
+// keeping the source extension would leave re-encoded files named after a
+// codec they no longer contain.
+func transformOne(
+	destination string,
+	source string,
+	f mp3files.File,
+	whiteList config.Tag,
+	blackList config.Tag,
+	naming config.Naming,
+	transcodeProfile config.TranscodeProfile,
+	m *manifest.DB,
+) (string, string, error) {
+	if m != nil {
+		if entry, err := m.Get(f.Name); err == nil && entry.Unchanged(f.Info.Size(), f.Info.ModTime()) {
+			return entry.DestPath, entry.TagFingerprint, nil
+		}
 	}
 
-	if tag.Year() != "" {
-		name += " (" + tag.Year() + ")"
+	info, err := loadTag(f)
+	if err != nil {
+		return "", "", fmt.Errorf("%w from %s: %v", ErrParseTag, f.Name, err)
 	}
 
-	disk := tag.GetTextFrame(frameIDDisk).Text
-	if disk != "" {
-		name += " - " + disk
+	if (len(whiteList) > 0 && !whiteList.Contains(info)) || (len(blackList) > 0 && blackList.Contains(info)) {
+		return "", "", nil
 	}
 
-	track := tag.GetTextFrame(frameIDTrack).Text
-	if track != "" {
-		if len(track) == 1 {
-			track = "0" + track
-		}
-
-		name += " - " + track
+	ext := filepath.Ext(f.Info.Name())
+	if transcodeProfile.TargetCodec != "" {
+		ext = transcode.Extension(transcodeProfile.TargetCodec)
 	}
 
-	return replaceChars(name + " - " + tag.Title() + ext), nil
-}
+	data := buildTemplateData(info, ext)
 
-func replaceChars(s string) string {
-	chars := map[string]string{
-		":":  ";",
-		"\\": "",
-		"/":  "",
-		"?":  "¿",
-		"\"": ",",
-		"'":  ",",
-		"*":  "x",
-		"+":  "x",
-		"[":  "(",
-		"]":  ")",
-		">":  "-",
-		"<":  "-",
-		"|":  "-",
+	folder, err := renderFolder(data, naming)
+	if err != nil {
+		return "", "", fmt.Errorf("%w from %s: %v", ErrParseTag, f.Name, err)
 	}
 
-	for k, v := range chars {
-		s = strings.Replace(s, k, v, -1)
+	name, err := renderName(data, naming)
+	if err != nil {
+		return "", "", fmt.Errorf("%w from %s: %v", ErrParseTag, f.Name, err)
 	}
 
-	return s
+	return filepath.Join(destination, folder, name), tagFingerprint(info), nil
+}
+
+// tagFingerprint builds a short string identifying info's content. It is
+// stored as the manifest entry's TagFingerprint; manifest.Verify and
+// manifest.Prune do not read it.
+func tagFingerprint(info tagreader.Info) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s", info.Artist, info.Album, info.Title, info.Track, info.Disc)
+}
+
+// loadTag reads normalized tag info for f from whichever backend Registry
+// resolves for f's extension.
+func loadTag(f mp3files.File) (tagreader.Info, error) {
+	return Registry.Read(f.Name)
 }